@@ -0,0 +1,94 @@
+// Package cgroups creates and tears down the cgroup(s) used to enforce a
+// container's resource limits, supporting both cgroup v1 (one hierarchy per
+// controller) and the v2 unified hierarchy.
+package cgroups
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// cgroupRoot is the parent directory all ns-process cgroups are created
+// under, mirroring the /var/run/ns-process/<id> layout used for state.
+const cgroupRoot = "ns-process"
+
+// Resources is the subset of a container Spec describing its cgroup limits.
+// A nil field leaves that controller unconfigured.
+type Resources struct {
+	Memory *MemoryResources `json:"memory,omitempty"`
+	CPU    *CPUResources    `json:"cpu,omitempty"`
+	Pids   *PidsResources   `json:"pids,omitempty"`
+	BlkIO  *BlkIOResources  `json:"blkio,omitempty"`
+}
+
+// MemoryResources caps the container's memory usage, in bytes.
+type MemoryResources struct {
+	Limit int64 `json:"limit,omitempty"`
+}
+
+// CPUResources caps the container's CPU usage. Shares is the relative
+// weight used by both v1 and v2; Quota is a hard microsecond-per-period
+// cap, v1-style (negative/zero means unlimited).
+type CPUResources struct {
+	Shares uint64 `json:"shares,omitempty"`
+	Quota  int64  `json:"quota,omitempty"`
+}
+
+// PidsResources caps the number of tasks the container may fork.
+type PidsResources struct {
+	Max int64 `json:"max,omitempty"`
+}
+
+// BlkIOResources sets the container's relative block IO weight.
+type BlkIOResources struct {
+	Weight uint16 `json:"weight,omitempty"`
+}
+
+// Manager creates, populates and tears down the cgroup(s) backing one
+// container. Callers get a Manager from NewManager, which autodetects
+// whether the host is running cgroup v1 or v2.
+type Manager interface {
+	// Apply writes pid into the cgroup's process list, putting it (and any
+	// children it forks) under the configured limits.
+	Apply(pid int) error
+	// Destroy removes the cgroup. It is safe to call even if Apply was
+	// never called.
+	Destroy() error
+}
+
+// NewManager creates the cgroup(s) for container id per resources and
+// returns a Manager for it, using v2 if the host's unified hierarchy is
+// mounted and falling back to v1 otherwise.
+func NewManager(id string, resources *Resources) (Manager, error) {
+	if resources == nil {
+		resources = &Resources{}
+	}
+
+	if isUnified, err := isCgroupV2(); err != nil {
+		return nil, err
+	} else if isUnified {
+		return newV2Manager(id, resources)
+	}
+	return newV1Manager(id, resources)
+}
+
+func writeFile(path, value string) error {
+	return os.WriteFile(path, []byte(value), 0o644)
+}
+
+func writeIfSet(path string, value int64, set bool) error {
+	if !set {
+		return nil
+	}
+	return writeFile(path, strconv.FormatInt(value, 10))
+}
+
+func mkdirAndJoin(base string, id string) (string, error) {
+	dir := filepath.Join(base, cgroupRoot, id)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("creating cgroup dir %s: %w", dir, err)
+	}
+	return dir, nil
+}
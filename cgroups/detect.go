@@ -0,0 +1,45 @@
+package cgroups
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const mountInfoPath = "/proc/self/mountinfo"
+
+// isCgroupV2 reports whether the host is running the unified (v2) cgroup
+// hierarchy, by looking for a single cgroup2 mount in mountinfo rather than
+// the per-controller cgroup mounts v1 uses.
+func isCgroupV2() (bool, error) {
+	f, err := os.Open(mountInfoPath)
+	if err != nil {
+		return false, fmt.Errorf("opening %s: %w", mountInfoPath, err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		// mountinfo fields are separated by a literal "-"; the filesystem
+		// type is the first field after it.
+		for i, field := range fields {
+			if field == "-" && i+1 < len(fields) {
+				switch fields[i+1] {
+				case "cgroup2":
+					return true, nil
+				case "cgroup":
+					return false, nil
+				}
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return false, fmt.Errorf("scanning %s: %w", mountInfoPath, err)
+	}
+
+	// No cgroup mounts found at all; default to v1 since that's the older,
+	// more conservative assumption.
+	return false, nil
+}
@@ -0,0 +1,96 @@
+package cgroups
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// v1Root is the cgroup v1 mount point; overridden in tests so the resource
+// flag logic can be exercised without writing under /sys/fs/cgroup.
+var v1Root = "/sys/fs/cgroup"
+
+// v1Manager creates one cgroup directory per requested controller under
+// /sys/fs/cgroup/<controller>/ns-process/<id>.
+type v1Manager struct {
+	id   string
+	dirs []string
+}
+
+func newV1Manager(id string, resources *Resources) (Manager, error) {
+	m := &v1Manager{id: id}
+
+	if resources.Memory != nil {
+		dir, err := mkdirAndJoin(filepath.Join(v1Root, "memory"), id)
+		if err != nil {
+			return nil, err
+		}
+		m.dirs = append(m.dirs, dir)
+		if err := writeIfSet(filepath.Join(dir, "memory.limit_in_bytes"), resources.Memory.Limit, resources.Memory.Limit != 0); err != nil {
+			return nil, err
+		}
+	}
+
+	if resources.CPU != nil {
+		dir, err := mkdirAndJoin(filepath.Join(v1Root, "cpu"), id)
+		if err != nil {
+			return nil, err
+		}
+		m.dirs = append(m.dirs, dir)
+		if resources.CPU.Shares != 0 {
+			if err := writeFile(filepath.Join(dir, "cpu.shares"), strconv.FormatUint(resources.CPU.Shares, 10)); err != nil {
+				return nil, err
+			}
+		}
+		if resources.CPU.Quota != 0 {
+			if err := writeFile(filepath.Join(dir, "cpu.cfs_quota_us"), strconv.FormatInt(resources.CPU.Quota, 10)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if resources.Pids != nil {
+		dir, err := mkdirAndJoin(filepath.Join(v1Root, "pids"), id)
+		if err != nil {
+			return nil, err
+		}
+		m.dirs = append(m.dirs, dir)
+		if err := writeIfSet(filepath.Join(dir, "pids.max"), resources.Pids.Max, resources.Pids.Max != 0); err != nil {
+			return nil, err
+		}
+	}
+
+	if resources.BlkIO != nil {
+		dir, err := mkdirAndJoin(filepath.Join(v1Root, "blkio"), id)
+		if err != nil {
+			return nil, err
+		}
+		m.dirs = append(m.dirs, dir)
+		if resources.BlkIO.Weight != 0 {
+			if err := writeFile(filepath.Join(dir, "blkio.weight"), strconv.FormatUint(uint64(resources.BlkIO.Weight), 10)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return m, nil
+}
+
+func (m *v1Manager) Apply(pid int) error {
+	pidStr := strconv.Itoa(pid)
+	for _, dir := range m.dirs {
+		if err := writeFile(filepath.Join(dir, "cgroup.procs"), pidStr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *v1Manager) Destroy() error {
+	for _, dir := range m.dirs {
+		if err := os.Remove(dir); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
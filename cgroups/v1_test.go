@@ -0,0 +1,72 @@
+package cgroups
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// withV1Root points v1Root at a temp directory for the duration of the
+// test, so newV1Manager's writes land somewhere other than the real
+// /sys/fs/cgroup.
+func withV1Root(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	old := v1Root
+	v1Root = dir
+	t.Cleanup(func() { v1Root = old })
+	return dir
+}
+
+func TestNewV1ManagerSkipsUnsetFields(t *testing.T) {
+	root := withV1Root(t)
+
+	resources := &Resources{
+		Memory: &MemoryResources{},
+		Pids:   &PidsResources{},
+		BlkIO:  &BlkIOResources{},
+	}
+
+	if _, err := newV1Manager("c1", resources); err != nil {
+		t.Fatalf("newV1Manager: %v", err)
+	}
+
+	for _, path := range []string{
+		filepath.Join(root, "memory", cgroupRoot, "c1", "memory.limit_in_bytes"),
+		filepath.Join(root, "pids", cgroupRoot, "c1", "pids.max"),
+		filepath.Join(root, "blkio", cgroupRoot, "c1", "blkio.weight"),
+	} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("expected %s to not be written when unset, got err=%v", path, err)
+		}
+	}
+}
+
+func TestNewV1ManagerWritesSetFields(t *testing.T) {
+	root := withV1Root(t)
+
+	resources := &Resources{
+		Memory: &MemoryResources{Limit: 1 << 20},
+		Pids:   &PidsResources{Max: 64},
+		BlkIO:  &BlkIOResources{Weight: 500},
+	}
+
+	if _, err := newV1Manager("c1", resources); err != nil {
+		t.Fatalf("newV1Manager: %v", err)
+	}
+
+	cases := map[string]string{
+		filepath.Join(root, "memory", cgroupRoot, "c1", "memory.limit_in_bytes"): "1048576",
+		filepath.Join(root, "pids", cgroupRoot, "c1", "pids.max"):                "64",
+		filepath.Join(root, "blkio", cgroupRoot, "c1", "blkio.weight"):           "500",
+	}
+	for path, want := range cases {
+		got, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("reading %s: %v", path, err)
+		}
+		if string(got) != want {
+			t.Errorf("%s = %q, want %q", path, got, want)
+		}
+	}
+}
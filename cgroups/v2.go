@@ -0,0 +1,109 @@
+package cgroups
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// v2Root is the cgroup v2 mount point; overridden in tests so the resource
+// flag logic can be exercised without writing under /sys/fs/cgroup.
+var v2Root = "/sys/fs/cgroup"
+
+// v2Manager creates a single cgroup directory under the unified hierarchy,
+// /sys/fs/cgroup/ns-process/<id>, enabling whichever controllers the
+// requested resources need via cgroup.subtree_control on the parent.
+type v2Manager struct {
+	dir string
+}
+
+func newV2Manager(id string, resources *Resources) (Manager, error) {
+	dir, err := mkdirAndJoin(v2Root, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := enableControllers(resources); err != nil {
+		return nil, err
+	}
+
+	if resources.Memory != nil {
+		if err := writeIfSet(filepath.Join(dir, "memory.max"), resources.Memory.Limit, resources.Memory.Limit != 0); err != nil {
+			return nil, err
+		}
+	}
+
+	if resources.CPU != nil {
+		if resources.CPU.Shares != 0 {
+			// cpu.weight is [1,10000]; cgroup v1's cpu.shares is
+			// [2,262144] around a default of 1024 - rescale accordingly.
+			weight := (resources.CPU.Shares*9999)/262144 + 1
+			if err := writeFile(filepath.Join(dir, "cpu.weight"), strconv.FormatUint(weight, 10)); err != nil {
+				return nil, err
+			}
+		}
+		if resources.CPU.Quota != 0 {
+			// cpu.max takes "max" for an unlimited quota rather than v1's -1.
+			quota := strconv.FormatInt(resources.CPU.Quota, 10)
+			if resources.CPU.Quota < 0 {
+				quota = "max"
+			}
+			if err := writeFile(filepath.Join(dir, "cpu.max"), quota+" 100000"); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if resources.Pids != nil {
+		if err := writeIfSet(filepath.Join(dir, "pids.max"), resources.Pids.Max, resources.Pids.Max != 0); err != nil {
+			return nil, err
+		}
+	}
+
+	if resources.BlkIO != nil && resources.BlkIO.Weight != 0 {
+		if err := writeFile(filepath.Join(dir, "io.bfq.weight"), strconv.FormatUint(uint64(resources.BlkIO.Weight), 10)); err != nil {
+			return nil, err
+		}
+	}
+
+	return &v2Manager{dir: dir}, nil
+}
+
+// enableControllers turns on the controllers needed by resources in the
+// parent's cgroup.subtree_control, which is required before they can be
+// configured in any child cgroup under the v2 unified hierarchy.
+func enableControllers(resources *Resources) error {
+	var controllers []string
+	if resources.Memory != nil {
+		controllers = append(controllers, "+memory")
+	}
+	if resources.CPU != nil {
+		controllers = append(controllers, "+cpu")
+	}
+	if resources.Pids != nil {
+		controllers = append(controllers, "+pids")
+	}
+	if resources.BlkIO != nil {
+		controllers = append(controllers, "+io")
+	}
+
+	subtreeControl := filepath.Join(v2Root, cgroupRoot, "cgroup.subtree_control")
+	for _, controller := range controllers {
+		if err := writeFile(subtreeControl, controller); err != nil {
+			return fmt.Errorf("enabling %s controller: %w", controller, err)
+		}
+	}
+	return nil
+}
+
+func (m *v2Manager) Apply(pid int) error {
+	return writeFile(filepath.Join(m.dir, "cgroup.procs"), strconv.Itoa(pid))
+}
+
+func (m *v2Manager) Destroy() error {
+	if err := os.Remove(m.dir); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package cgroups
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// withV2Root points v2Root at a temp directory for the duration of the
+// test, so newV2Manager's writes land somewhere other than the real
+// /sys/fs/cgroup.
+func withV2Root(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	old := v2Root
+	v2Root = dir
+	t.Cleanup(func() { v2Root = old })
+	return dir
+}
+
+func TestNewV2ManagerSkipsUnsetFields(t *testing.T) {
+	withV2Root(t)
+
+	resources := &Resources{
+		Memory: &MemoryResources{},
+		Pids:   &PidsResources{},
+		BlkIO:  &BlkIOResources{},
+	}
+
+	m, err := newV2Manager("c1", resources)
+	if err != nil {
+		t.Fatalf("newV2Manager: %v", err)
+	}
+	v2m := m.(*v2Manager)
+
+	for _, name := range []string{"memory.max", "pids.max", "io.bfq.weight"} {
+		path := filepath.Join(v2m.dir, name)
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("expected %s to not be written when unset, got err=%v", path, err)
+		}
+	}
+}
+
+func TestNewV2ManagerWritesSetFields(t *testing.T) {
+	withV2Root(t)
+
+	resources := &Resources{
+		Memory: &MemoryResources{Limit: 1 << 20},
+		Pids:   &PidsResources{Max: 64},
+		BlkIO:  &BlkIOResources{Weight: 500},
+	}
+
+	m, err := newV2Manager("c1", resources)
+	if err != nil {
+		t.Fatalf("newV2Manager: %v", err)
+	}
+	v2m := m.(*v2Manager)
+
+	cases := map[string]string{"memory.max": "1048576", "pids.max": "64", "io.bfq.weight": "500"}
+	for name, want := range cases {
+		path := filepath.Join(v2m.dir, name)
+		got, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("reading %s: %v", path, err)
+		}
+		if string(got) != want {
+			t.Errorf("%s = %q, want %q", path, got, want)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+// Command ns-process drives the container package from the command line:
+//
+//	ns-process run <spec.json>     start a new container from a spec
+//	ns-process list                list known containers and their status
+//	ns-process exec <id> -- <cmd>  run a command inside a running container
+//	ns-process pull <tarball>      import a layer tarball into the image store
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/tc-archive/linux-namespaces-in-go/container"
+	"github.com/tc-archive/linux-namespaces-in-go/image"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(1)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "run":
+		err = runCmd(os.Args[2:])
+	case "list":
+		err = listCmd()
+	case "exec":
+		err = execCmd(os.Args[2:])
+	case "pull":
+		err = pullCmd(os.Args[2:])
+	default:
+		usage()
+		os.Exit(1)
+	}
+
+	if err != nil {
+		fmt.Printf("Error: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Println("usage: ns-process run <spec.json> | list | exec <id> -- <cmd> [args...] | pull <tarball>")
+}
+
+// runCmd starts a new, named container from a spec file. The container's id
+// is a fresh random one from container.GenerateID.
+func runCmd(args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("usage: ns-process run <spec.json>")
+	}
+
+	specFile := args[0]
+	spec, err := container.LoadSpec(specFile)
+	if err != nil {
+		return err
+	}
+
+	id := container.GenerateID()
+	c := container.New(id, spec)
+
+	fmt.Printf("Running %s...\n", id)
+
+	if err := c.Start(); err != nil {
+		return err
+	}
+
+	return c.Wait()
+}
+
+// listCmd prints the id, pid and status of every known container.
+func listCmd() error {
+	states, err := container.List()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%-36s %-8s %s\n", "ID", "PID", "STATUS")
+	for _, s := range states {
+		fmt.Printf("%-36s %-8d %s\n", s.ID, s.Pid, s.Status)
+	}
+	return nil
+}
+
+// execCmd joins an already-running container and runs cmd inside it.
+func execCmd(args []string) error {
+	dashdash := -1
+	for i, a := range args {
+		if a == "--" {
+			dashdash = i
+			break
+		}
+	}
+	if dashdash < 1 || dashdash == len(args)-1 {
+		return fmt.Errorf("usage: ns-process exec <id> -- <cmd> [args...]")
+	}
+
+	id := args[0]
+	cmdArgs := args[dashdash+1:]
+
+	c, err := container.Load(id)
+	if err != nil {
+		return err
+	}
+
+	return c.Exec(cmdArgs[0], cmdArgs[1:])
+}
+
+// pullCmd imports a layer tarball into the image store and prints its
+// digest, ready to be referenced from a manifest's `layers` list.
+func pullCmd(args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("usage: ns-process pull <tarball>")
+	}
+
+	digest, err := image.Pull(args[0])
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(digest)
+	return nil
+}
@@ -0,0 +1,33 @@
+package container
+
+import "os"
+
+// barrierFd is the file descriptor nsInitialisation reads its one-byte
+// ready signal from. It is passed to the child as the first entry of
+// cmd.ExtraFiles, which always lands at fd 3 (0, 1, 2 are stdio).
+const barrierFd = 3
+
+// newBarrier returns the read/write ends of the pipe used to hold the
+// container's init process at nsInitialisation until Start has finished
+// doing parent-side setup - attaching networking, joining cgroups - that
+// the child must not race past.
+func newBarrier() (r, w *os.File, err error) {
+	return os.Pipe()
+}
+
+// release signals the child past its init barrier.
+func release(w *os.File) error {
+	_, err := w.Write([]byte{0})
+	return err
+}
+
+// waitForBarrier blocks the child until release is called on the parent's
+// end of the pipe.
+func waitForBarrier() error {
+	f := os.NewFile(barrierFd, "barrier")
+	defer f.Close()
+
+	buf := make([]byte, 1)
+	_, err := f.Read(buf)
+	return err
+}
@@ -0,0 +1,221 @@
+// Package container is a small library for starting and joining Linux
+// namespace-isolated processes, built around a JSON container Spec rather
+// than the hardcoded single-container flow the original command line tool
+// grew out of.
+package container
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+
+	reexec "github.com/docker/docker/pkg/reexec"
+
+	"github.com/tc-archive/linux-namespaces-in-go/cgroups"
+	"github.com/tc-archive/linux-namespaces-in-go/image"
+	"github.com/tc-archive/linux-namespaces-in-go/network"
+)
+
+// Container is a handle onto one namespaced process, either one this
+// process just started or one reattached to via Load.
+type Container struct {
+	ID   string
+	Spec *Spec
+
+	cmd         *exec.Cmd
+	cgroups     cgroups.Manager
+	overlayRoot string
+}
+
+// New creates a Container from spec, assigning it id. The container is not
+// started until Start is called.
+func New(id string, spec *Spec) *Container {
+	return &Container{ID: id, Spec: spec}
+}
+
+// Load reattaches to a container previously created with New and Start,
+// using the spec and pid recorded under its state directory.
+func Load(id string) (*Container, error) {
+	spec, err := LoadSpec(specPath(id))
+	if err != nil {
+		return nil, fmt.Errorf("loading container %s: %w", id, err)
+	}
+
+	state, err := loadState(id)
+	if err != nil {
+		return nil, fmt.Errorf("loading container %s: %w", id, err)
+	}
+
+	return &Container{ID: id, Spec: spec, cmd: &exec.Cmd{Process: &os.Process{Pid: state.Pid}}}, nil
+}
+
+// Start snapshots the spec into the container's state directory, builds the
+// SysProcAttr.Cloneflags for the requested namespaces and reexecs into
+// nsInitialisation, which finishes setting the new process up (mounts,
+// pivot_root, hostname, ...) before execing Spec.Command.
+//
+// Start returns once the reexec'd process has been launched; callers that
+// need to wait for it to exit should call Wait.
+func (c *Container) Start() error {
+	if err := os.MkdirAll(containerDir(c.ID), 0o755); err != nil {
+		return fmt.Errorf("creating state dir for %s: %w", c.ID, err)
+	}
+
+	if c.Spec.Image != nil {
+		manifest, err := image.LoadManifest(c.Spec.Image.Manifest)
+		if err != nil {
+			return fmt.Errorf("starting container %s: %w", c.ID, err)
+		}
+		merged, err := image.MountOverlay(c.ID, manifest)
+		if err != nil {
+			return fmt.Errorf("starting container %s: %w", c.ID, err)
+		}
+		c.overlayRoot = merged
+		c.Spec.Rootfs = merged
+
+		applyImageConfig(c.Spec, manifest.Config)
+	}
+
+	if c.Spec.Command == "" {
+		return fmt.Errorf("starting container %s: command is required (set spec.command or an image config.cmd)", c.ID)
+	}
+
+	if err := c.Spec.Save(specPath(c.ID)); err != nil {
+		return fmt.Errorf("starting container %s: %w", c.ID, err)
+	}
+
+	cmd := reexec.Command("nsInitialisation", c.ID)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = c.Spec.Env
+
+	flags := cloneFlags(c.Spec.Namespaces)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Cloneflags: flags}
+	if flags&syscall.CLONE_NEWUSER != 0 {
+		cmd.SysProcAttr.UidMappings, cmd.SysProcAttr.GidMappings = createSysProcIDMappings(0, 0, c.Spec.UIDMappings, c.Spec.GIDMappings)
+	}
+
+	mgr, err := cgroups.NewManager(c.ID, c.Spec.Resources)
+	if err != nil {
+		return fmt.Errorf("creating cgroups for %s: %w", c.ID, err)
+	}
+	c.cgroups = mgr
+
+	// nsInitialisation blocks on this pipe immediately after the namespaces
+	// are set up, so that it doesn't race ahead of the networking and
+	// cgroup setup we still need to do from the parent side.
+	barrierR, barrierW, err := newBarrier()
+	if err != nil {
+		return fmt.Errorf("creating init barrier for %s: %w", c.ID, err)
+	}
+	cmd.ExtraFiles = []*os.File{barrierR}
+
+	// Instead of running the command in one step, we Start() it and Wait()
+	// for it to exit later, so that callers can do work - attaching
+	// networking, joining cgroups - against the child's pid in between.
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("starting container %s: %w", c.ID, err)
+	}
+	c.cmd = cmd
+	barrierR.Close()
+
+	// Join the cgroup immediately, before the container's command has a
+	// chance to run, so the limits are in place from its very first
+	// instruction.
+	if err := c.cgroups.Apply(cmd.Process.Pid); err != nil {
+		return fmt.Errorf("applying cgroups for %s: %w", c.ID, err)
+	}
+
+	if c.Spec.Network != nil {
+		if err := network.Attach(cmd.Process.Pid, c.Spec.Network); err != nil {
+			return fmt.Errorf("attaching network for %s: %w", c.ID, err)
+		}
+	}
+
+	if err := release(barrierW); err != nil {
+		return fmt.Errorf("releasing init barrier for %s: %w", c.ID, err)
+	}
+	barrierW.Close()
+
+	return saveState(c.ID, &State{ID: c.ID, Pid: cmd.Process.Pid, Status: "running"})
+}
+
+// applyImageConfig fills in any of Command, Args, Env and Workdir the spec
+// didn't already set from the image's manifest config, so a container
+// started from an image without its own command/env inherits the image's
+// defaults. Spec values always win over the image's on a key collision.
+func applyImageConfig(spec *Spec, cfg image.Config) {
+	if spec.Command == "" && len(cfg.Cmd) > 0 {
+		spec.Command = cfg.Cmd[0]
+		spec.Args = cfg.Cmd[1:]
+	}
+	if len(cfg.Env) > 0 {
+		spec.Env = mergeEnv(spec.Env, cfg.Env)
+	}
+	if spec.Workdir == "" {
+		spec.Workdir = cfg.Workdir
+	}
+}
+
+// mergeEnv combines specEnv and imageEnv, keeping specEnv's entries first:
+// for a duplicate key, a process's getenv returns the first match in envp,
+// so specEnv has to lead for its values to actually win collisions against
+// imageEnv, as documented on Spec.Env.
+func mergeEnv(specEnv, imageEnv []string) []string {
+	keys := make(map[string]bool, len(specEnv))
+	for _, kv := range specEnv {
+		keys[envKey(kv)] = true
+	}
+
+	merged := append([]string{}, specEnv...)
+	for _, kv := range imageEnv {
+		if !keys[envKey(kv)] {
+			merged = append(merged, kv)
+		}
+	}
+	return merged
+}
+
+// envKey returns the "KEY" part of a "KEY=VALUE" environment entry.
+func envKey(kv string) string {
+	if i := strings.IndexByte(kv, '='); i >= 0 {
+		return kv[:i]
+	}
+	return kv
+}
+
+// Pid returns the pid of the container's init process.
+func (c *Container) Pid() int {
+	return c.cmd.Process.Pid
+}
+
+// Wait blocks until the container's init process exits and records its
+// final status.
+func (c *Container) Wait() error {
+	err := c.cmd.Wait()
+
+	if c.overlayRoot != "" {
+		if unmountErr := image.UnmountOverlay(c.overlayRoot); unmountErr != nil {
+			fmt.Printf("Error unmounting overlay for %s - %s\n", c.ID, unmountErr)
+		}
+	}
+
+	if c.cgroups != nil {
+		if destroyErr := c.cgroups.Destroy(); destroyErr != nil {
+			fmt.Printf("Error destroying cgroups for %s - %s\n", c.ID, destroyErr)
+		}
+	}
+
+	status := "stopped"
+	if saveErr := saveState(c.ID, &State{ID: c.ID, Pid: c.cmd.Process.Pid, Status: status}); saveErr != nil {
+		fmt.Printf("Error recording stopped state for %s - %s\n", c.ID, saveErr)
+	}
+
+	if err != nil {
+		return fmt.Errorf("waiting for container %s: %w", c.ID, err)
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package container
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/tc-archive/linux-namespaces-in-go/image"
+)
+
+func TestApplyImageConfigSpecEnvWinsCollision(t *testing.T) {
+	spec := &Spec{Env: []string{"FOO=spec-value", "ONLY_SPEC=1"}}
+	cfg := image.Config{Env: []string{"FOO=image-value", "ONLY_IMAGE=2"}}
+
+	applyImageConfig(spec, cfg)
+
+	want := []string{"FOO=spec-value", "ONLY_SPEC=1", "ONLY_IMAGE=2"}
+	if !reflect.DeepEqual(spec.Env, want) {
+		t.Errorf("spec.Env = %v, want %v", spec.Env, want)
+	}
+}
+
+func TestApplyImageConfigFillsCommandAndWorkdir(t *testing.T) {
+	spec := &Spec{}
+	cfg := image.Config{Cmd: []string{"/bin/sh", "-c", "echo hi"}, Workdir: "/app"}
+
+	applyImageConfig(spec, cfg)
+
+	if spec.Command != "/bin/sh" {
+		t.Errorf("Command = %q, want /bin/sh", spec.Command)
+	}
+	if want := []string{"-c", "echo hi"}; !reflect.DeepEqual(spec.Args, want) {
+		t.Errorf("Args = %v, want %v", spec.Args, want)
+	}
+	if spec.Workdir != "/app" {
+		t.Errorf("Workdir = %q, want /app", spec.Workdir)
+	}
+}
+
+func TestApplyImageConfigDoesNotOverrideSpecCommand(t *testing.T) {
+	spec := &Spec{Command: "/my/own/cmd", Workdir: "/custom"}
+	cfg := image.Config{Cmd: []string{"/bin/sh"}, Workdir: "/app"}
+
+	applyImageConfig(spec, cfg)
+
+	if spec.Command != "/my/own/cmd" {
+		t.Errorf("Command = %q, want /my/own/cmd", spec.Command)
+	}
+	if spec.Workdir != "/custom" {
+		t.Errorf("Workdir = %q, want /custom", spec.Workdir)
+	}
+}
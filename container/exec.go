@@ -0,0 +1,17 @@
+package container
+
+import (
+	"fmt"
+
+	"github.com/tc-archive/linux-namespaces-in-go/nsenter"
+)
+
+// Exec runs cmd with args inside an already-running container, joining its
+// namespaces via setns(2). See the nsenter package for why that has to
+// happen from a cgo constructor rather than ordinary Go code.
+func (c *Container) Exec(cmd string, args []string) error {
+	if err := nsenter.Join(c.Pid(), c.Spec.Env, cmd, args); err != nil {
+		return fmt.Errorf("exec into container %s: %w", c.ID, err)
+	}
+	return nil
+}
@@ -0,0 +1,18 @@
+package container
+
+import (
+	"crypto/rand"
+	"fmt"
+)
+
+// GenerateID returns a short random hex id for a new container, in the
+// style of early Docker container ids.
+func GenerateID() string {
+	buf := make([]byte, 6)
+	if _, err := rand.Read(buf); err != nil {
+		// crypto/rand reading from the OS should never fail in practice;
+		// fall back to a fixed id rather than leaving the container unnamed.
+		return "ns-process"
+	}
+	return fmt.Sprintf("%x", buf)
+}
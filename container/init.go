@@ -0,0 +1,177 @@
+package container
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+
+	reexec "github.com/docker/docker/pkg/reexec"
+
+	"github.com/tc-archive/linux-namespaces-in-go/security"
+)
+
+// Register the reexec entry point used by Start. This runs in *every*
+// process image built from this package, including the parent, which is
+// why reexec.Init() guards it from recursing.
+func init() {
+	reexec.Register("nsInitialisation", nsInitialisation)
+	if reexec.Init() {
+		// Prevents infinite loop initialisation.
+		os.Exit(0)
+	}
+}
+
+// nsInitialisation is run by reexec inside the freshly cloned namespaces,
+// before Spec.Command is execed. It loads the spec back out of the
+// container's state directory (saved by Start) using the id passed as
+// os.Args[1], then finishes setting up the container in order: mounts,
+// pivot_root, hostname, capability/seccomp hardening, before finally
+// running the requested command.
+func nsInitialisation() {
+	fmt.Printf("\n>> initialising namespace <<\n\n")
+
+	id := os.Args[1]
+	spec, err := LoadSpec(specPath(id))
+	if err != nil {
+		fmt.Printf("Error loading spec for %s - %s\n", id, err)
+		os.Exit(1)
+	}
+
+	if err := mountProc(spec.Rootfs); err != nil {
+		fmt.Printf("Error mounting /proc - %s\n", err)
+		os.Exit(1)
+	}
+
+	if err := mountExtra(spec.Rootfs, spec.Mounts); err != nil {
+		fmt.Printf("Error applying spec mounts - %s\n", err)
+		os.Exit(1)
+	}
+
+	if err := pivotRoot(spec.Rootfs); err != nil {
+		fmt.Printf("Error running pivot_root - %s\n", err)
+		os.Exit(1)
+	}
+
+	if spec.Hostname != "" {
+		if err := syscall.Sethostname([]byte(spec.Hostname)); err != nil {
+			fmt.Printf("Error setting hostname - %s\n", err)
+			os.Exit(1)
+		}
+	}
+	if spec.Domainname != "" {
+		if err := syscall.Setdomainname([]byte(spec.Domainname)); err != nil {
+			fmt.Printf("Error setting domainname - %s\n", err)
+			os.Exit(1)
+		}
+	}
+
+	// Block here until the parent has finished cgroup and network setup
+	// against our pid, so we never start running the container's command
+	// with an interface that's only half configured.
+	if err := waitForBarrier(); err != nil {
+		fmt.Printf("Error waiting for init barrier - %s\n", err)
+		os.Exit(1)
+	}
+
+	// Harden last, in the order that actually matters: drop capabilities
+	// first, then no_new_privs, then seccomp, since installing a seccomp
+	// filter without no_new_privs requires CAP_SYS_ADMIN.
+	if err := security.DropCapabilities(spec.Capabilities); err != nil {
+		fmt.Printf("Error dropping capabilities - %s\n", err)
+		os.Exit(1)
+	}
+	if err := security.SetNoNewPrivs(); err != nil {
+		fmt.Printf("Error setting no_new_privs - %s\n", err)
+		os.Exit(1)
+	}
+	if err := security.LoadSeccomp(spec.Seccomp); err != nil {
+		fmt.Printf("Error loading seccomp filter - %s\n", err)
+		os.Exit(1)
+	}
+
+	run(spec)
+}
+
+// mountProc mounts a fresh /proc inside newroot, so that tools like `ps`
+// report on the new PID namespace rather than the host's.
+func mountProc(newroot string) error {
+	target := filepath.Join(newroot, "proc")
+	if err := os.MkdirAll(target, 0o755); err != nil {
+		return err
+	}
+	return syscall.Mount("proc", target, "proc", 0, "")
+}
+
+// mountExtra applies the bind/virtual mounts listed in a Spec, in order.
+func mountExtra(newroot string, mounts []Mount) error {
+	for _, m := range mounts {
+		target := filepath.Join(newroot, m.Destination)
+		if err := os.MkdirAll(target, 0o755); err != nil {
+			return fmt.Errorf("mounting %s: %w", m.Destination, err)
+		}
+
+		var flags uintptr
+		data := ""
+		for _, opt := range m.Options {
+			if opt == "bind" {
+				flags |= syscall.MS_BIND
+			}
+		}
+
+		if err := syscall.Mount(m.Source, target, m.Type, flags, data); err != nil {
+			return fmt.Errorf("mounting %s: %w", m.Destination, err)
+		}
+	}
+	return nil
+}
+
+// pivotRoot swaps newroot in as the process's / via pivot_root(2), moving
+// the old root to .pivot_root under newroot and then unmounting it - the
+// standard dance for making a new rootfs stick without leaking the host's
+// filesystem tree into the container.
+func pivotRoot(newroot string) error {
+	putOld := filepath.Join(newroot, ".pivot_root")
+
+	// Bind mount newroot to itself - pivot_root(2) requires its new_root
+	// argument to be a mount point.
+	if err := syscall.Mount(newroot, newroot, "", syscall.MS_BIND|syscall.MS_REC, ""); err != nil {
+		return fmt.Errorf("bind mounting %s: %w", newroot, err)
+	}
+
+	if err := os.MkdirAll(putOld, 0o700); err != nil {
+		return err
+	}
+
+	if err := syscall.PivotRoot(newroot, putOld); err != nil {
+		return fmt.Errorf("pivot_root: %w", err)
+	}
+
+	if err := os.Chdir("/"); err != nil {
+		return fmt.Errorf("chdir to new root: %w", err)
+	}
+
+	putOld = "/.pivot_root"
+	if err := syscall.Unmount(putOld, syscall.MNT_DETACH); err != nil {
+		return fmt.Errorf("unmounting old root: %w", err)
+	}
+
+	return os.RemoveAll(putOld)
+}
+
+// run execs the container's command as the final step of initialisation.
+func run(spec *Spec) {
+	cmd := exec.Command(spec.Command, spec.Args...)
+
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = spec.Env
+	cmd.Dir = spec.Workdir
+
+	if err := cmd.Run(); err != nil {
+		fmt.Printf("Error running %s - %s\n", spec.Command, err)
+		os.Exit(1)
+	}
+}
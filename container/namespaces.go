@@ -0,0 +1,71 @@
+package container
+
+import "syscall"
+
+// namespaceFlags maps the short names used in a Spec's `namespaces` list to
+// their clone(2) flag, following the namespaces(7) table:
+//
+// Namespace | Constant        | Isolates
+// ----------+-----------------+-----------------------------------------------
+// Cgroup    | CLONE_NEWCGROUP | Isolate cgroup root directory.
+// IPC       | CLONE_NEWIPC    | Isolate IPC resources, POSIX message queues.
+// Network   | CLONE_NEWNET    | Isolate network devices, stacks, ports, etc.
+// Mount     | CLONE_NEWNS     | Isolate filesystem mount points.
+// PID       | CLONE_NEWPID    | Process PID number space.
+// User      | CLONE_NEWUSER   | Isolate UID/GID number spaces.
+// UTS       | CLONE_NEWUTS    | Isolate hostname and NIS domainname.
+var namespaceFlags = map[string]uintptr{
+	"NEWCGROUP": syscall.CLONE_NEWCGROUP,
+	"NEWIPC":    syscall.CLONE_NEWIPC,
+	"NEWNET":    syscall.CLONE_NEWNET,
+	"NEWNS":     syscall.CLONE_NEWNS,
+	"NEWPID":    syscall.CLONE_NEWPID,
+	"NEWUSER":   syscall.CLONE_NEWUSER,
+	"NEWUTS":    syscall.CLONE_NEWUTS,
+}
+
+// cloneFlags turns a Spec's `namespaces` list into the Cloneflags value for
+// SysProcAttr. Unrecognised names are ignored so that a spec written for a
+// newer version of ns-process degrades gracefully on an older binary.
+func cloneFlags(namespaces []string) uintptr {
+	var flags uintptr
+	for _, ns := range namespaces {
+		flags |= namespaceFlags[ns]
+	}
+	return flags
+}
+
+// createSysProcIDMappings builds the uid_map/gid_map entries for
+// SysProcAttr.UidMappings/GidMappings. When the spec supplies explicit
+// mappings those are used verbatim; otherwise a single-entry mapping from
+// the invoking uid/gid to the requested container uid/gid is used, which
+// gives the process root (uid=0,gid=0) status inside the container by
+// default:
+//
+// cat /etc/passwd | awk -F: '{printf "%s:%s:%s\n",$1,$3,$4}'
+func createSysProcIDMappings(containerUID, containerGID int, uidMaps, gidMaps []IDMap) ([]syscall.SysProcIDMap, []syscall.SysProcIDMap) {
+	return idMapsOrDefault(uidMaps, containerUID, syscall.Getuid()),
+		idMapsOrDefault(gidMaps, containerGID, syscall.Getgid())
+}
+
+func idMapsOrDefault(maps []IDMap, containerID, hostID int) []syscall.SysProcIDMap {
+	if len(maps) == 0 {
+		return []syscall.SysProcIDMap{
+			{
+				ContainerID: containerID,
+				HostID:      hostID,
+				Size:        1,
+			},
+		}
+	}
+
+	out := make([]syscall.SysProcIDMap, len(maps))
+	for i, m := range maps {
+		out[i] = syscall.SysProcIDMap{
+			ContainerID: m.ContainerID,
+			HostID:      m.HostID,
+			Size:        m.Size,
+		}
+	}
+	return out
+}
@@ -0,0 +1,142 @@
+package container
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/tc-archive/linux-namespaces-in-go/cgroups"
+	"github.com/tc-archive/linux-namespaces-in-go/network"
+	"github.com/tc-archive/linux-namespaces-in-go/security"
+)
+
+// ImageRef points a Spec at an image to assemble its rootfs from, in place
+// of a pre-existing Rootfs directory.
+type ImageRef struct {
+	// Manifest is the path to the image's manifest.json; its layers must
+	// already have been imported into the layer store with `ns-process
+	// pull`.
+	Manifest string `json:"manifest"`
+}
+
+// Spec is the on-disk JSON description of a container, in the spirit of the
+// early libcontainer `container.json` format. A Spec is loaded once, at
+// `ns-process run`; Start fills in the image-derived fields (Rootfs, and
+// any of Command, Args, Env and Workdir taken from the image config) before
+// snapshotting it, and it is not mutated after that - runtime state (pid,
+// status, ...) lives alongside it in State.
+type Spec struct {
+	// Rootfs is the path to the root filesystem the container's command
+	// will be pivoted into. Ignored if Image is set, in which case it is
+	// overwritten with the path of the assembled overlay once the
+	// container starts.
+	Rootfs string `json:"rootfs,omitempty"`
+
+	// Image assembles Rootfs from stacked layers via overlayfs instead of
+	// using a pre-existing rootfs directory.
+	Image *ImageRef `json:"image,omitempty"`
+
+	// Command is the path of the executable to run inside the container. If
+	// empty and Image is set, it is filled in from the image manifest's
+	// Config.Cmd once the manifest is loaded at Start time.
+	Command string `json:"command,omitempty"`
+	// Args are the arguments passed to Command, not including Command itself.
+	Args []string `json:"args,omitempty"`
+	// Env is the environment the command is started with, "KEY=VALUE" form.
+	// If Image is set, the image manifest's Config.Env entries whose keys
+	// are not already set here are appended to this at Start time, so
+	// entries here take precedence on key collisions.
+	Env []string `json:"env,omitempty"`
+	// Workdir is the working directory Command is run from, relative to
+	// Rootfs. If empty and Image is set, it is filled in from the image
+	// manifest's Config.Workdir.
+	Workdir string `json:"workdir,omitempty"`
+
+	// Hostname and Domainname are applied via sethostname(2)/setdomainname(2)
+	// once the UTS namespace has been unshared.
+	Hostname   string `json:"hostname,omitempty"`
+	Domainname string `json:"domainname,omitempty"`
+
+	// Namespaces lists the namespaces to unshare for this container, using
+	// the short names from namespaces(7): NEWNET, NEWNS, NEWUTS, NEWIPC,
+	// NEWPID, NEWUSER, NEWCGROUP. An empty list unshares nothing.
+	Namespaces []string `json:"namespaces"`
+
+	// UIDMappings and GIDMappings populate /proc/<pid>/uid_map and gid_map
+	// when NEWUSER is requested. A nil slice falls back to mapping the
+	// invoking uid/gid to root inside the container.
+	UIDMappings []IDMap `json:"uidMappings,omitempty"`
+	GIDMappings []IDMap `json:"gidMappings,omitempty"`
+
+	// Mounts are bind/virtual filesystems mounted into Rootfs before the
+	// command is started, applied in order.
+	Mounts []Mount `json:"mounts,omitempty"`
+
+	// Network is optional; a nil value leaves the container with only the
+	// loopback interface even when NEWNET is requested.
+	Network *network.Config `json:"network,omitempty"`
+
+	// Resources configures the cgroup limits applied to the container. A
+	// nil value leaves every controller unconfigured.
+	Resources *cgroups.Resources `json:"resources,omitempty"`
+
+	// Capabilities configures capability dropping applied after
+	// pivot_root, before the container's command is exec'd. A nil value
+	// leaves the process's capabilities untouched.
+	Capabilities *security.Capabilities `json:"capabilities,omitempty"`
+
+	// Seccomp configures a syscall filter installed after capabilities are
+	// dropped and no_new_privs is set, as the last hardening step before
+	// exec. A nil value installs no filter.
+	Seccomp *security.Seccomp `json:"seccomp,omitempty"`
+}
+
+// IDMap is a single line of a uid_map/gid_map file: Size contiguous ids
+// starting at HostID are mapped to ids starting at ContainerID.
+type IDMap struct {
+	ContainerID int `json:"containerID"`
+	HostID      int `json:"hostID"`
+	Size        int `json:"size"`
+}
+
+// Mount describes one filesystem to mount into the container's rootfs.
+type Mount struct {
+	Source      string   `json:"source"`
+	Destination string   `json:"destination"`
+	Type        string   `json:"type"`
+	Options     []string `json:"options,omitempty"`
+}
+
+// LoadSpec reads and validates a Spec from path.
+func LoadSpec(path string) (*Spec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading spec %s: %w", path, err)
+	}
+
+	var spec Spec
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return nil, fmt.Errorf("parsing spec %s: %w", path, err)
+	}
+
+	if spec.Rootfs == "" && spec.Image == nil {
+		return nil, fmt.Errorf("spec %s: one of rootfs or image is required", path)
+	}
+	// When Image is set, Command may be filled in later from the image
+	// manifest's Config.Cmd, so it isn't required up front.
+	if spec.Command == "" && spec.Image == nil {
+		return nil, fmt.Errorf("spec %s: command is required", path)
+	}
+
+	return &spec, nil
+}
+
+// Save writes the spec back out as JSON, used to snapshot it into a
+// container's state directory at `run` time.
+func (s *Spec) Save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshalling spec: %w", err)
+	}
+	return os.WriteFile(path, data, 0o644)
+}
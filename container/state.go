@@ -0,0 +1,87 @@
+package container
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// stateRoot is where per-container runtime state is kept, one directory per
+// container id: /var/run/ns-process/<id>/{spec.json,state.json}.
+const stateRoot = "/var/run/ns-process"
+
+// State is the runtime status of a container, persisted to state.json so
+// that `ns-process list` and `ns-process exec` can find a running container
+// without holding anything in memory.
+type State struct {
+	ID     string `json:"id"`
+	Pid    int    `json:"pid"`
+	Status string `json:"status"` // "running" or "stopped"
+}
+
+func containerDir(id string) string {
+	return filepath.Join(stateRoot, id)
+}
+
+func specPath(id string) string {
+	return filepath.Join(containerDir(id), "spec.json")
+}
+
+func statePath(id string) string {
+	return filepath.Join(containerDir(id), "state.json")
+}
+
+// saveState writes state.json, creating the container's state directory if
+// it doesn't already exist.
+func saveState(id string, state *State) error {
+	if err := os.MkdirAll(containerDir(id), 0o755); err != nil {
+		return fmt.Errorf("creating state dir for %s: %w", id, err)
+	}
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshalling state for %s: %w", id, err)
+	}
+
+	return os.WriteFile(statePath(id), data, 0o644)
+}
+
+// loadState reads back a container's state.json.
+func loadState(id string) (*State, error) {
+	data, err := os.ReadFile(statePath(id))
+	if err != nil {
+		return nil, fmt.Errorf("reading state for %s: %w", id, err)
+	}
+
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("parsing state for %s: %w", id, err)
+	}
+	return &state, nil
+}
+
+// List returns the recorded State of every container under stateRoot,
+// running or stopped.
+func List() ([]*State, error) {
+	entries, err := os.ReadDir(stateRoot)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading %s: %w", stateRoot, err)
+	}
+
+	var states []*State
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		state, err := loadState(entry.Name())
+		if err != nil {
+			continue
+		}
+		states = append(states, state)
+	}
+	return states, nil
+}
@@ -0,0 +1,45 @@
+// Package image assembles a container's rootfs from stacked, read-only
+// image layers plus a writable upper layer, using overlayfs - rather than
+// the single hardcoded rootfs directory the container package used to
+// require.
+package image
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Manifest describes an image as an ordered list of layers plus the
+// config to run it with, loosely following the OCI image layout.
+type Manifest struct {
+	// Layers are sha256 digests, lowest first, each a directory under the
+	// layer store populated by Pull.
+	Layers []string `json:"layers"`
+	Config Config   `json:"config"`
+}
+
+// Config is the subset of an image's config a Spec cares about.
+type Config struct {
+	Env     []string `json:"env,omitempty"`
+	Cmd     []string `json:"cmd,omitempty"`
+	Workdir string   `json:"workdir,omitempty"`
+}
+
+// LoadManifest reads and validates a Manifest from path.
+func LoadManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading manifest %s: %w", path, err)
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("parsing manifest %s: %w", path, err)
+	}
+	if len(manifest.Layers) == 0 {
+		return nil, fmt.Errorf("manifest %s: at least one layer is required", path)
+	}
+
+	return &manifest, nil
+}
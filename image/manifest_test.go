@@ -0,0 +1,70 @@
+package image
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeManifest(t *testing.T, manifest Manifest) string {
+	t.Helper()
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		t.Fatalf("marshalling manifest: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "manifest.json")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatalf("writing manifest: %v", err)
+	}
+	return path
+}
+
+func TestLoadManifestRequiresLayers(t *testing.T) {
+	path := writeManifest(t, Manifest{})
+	if _, err := LoadManifest(path); err == nil {
+		t.Fatal("expected an error for a manifest with no layers")
+	}
+}
+
+func TestLoadManifestParsesConfig(t *testing.T) {
+	path := writeManifest(t, Manifest{
+		Layers: []string{"abc123"},
+		Config: Config{
+			Env:     []string{"FOO=bar"},
+			Cmd:     []string{"/bin/sh", "-c", "echo hi"},
+			Workdir: "/app",
+		},
+	})
+
+	manifest, err := LoadManifest(path)
+	if err != nil {
+		t.Fatalf("LoadManifest: %v", err)
+	}
+
+	if len(manifest.Layers) != 1 || manifest.Layers[0] != "abc123" {
+		t.Errorf("Layers = %v, want [abc123]", manifest.Layers)
+	}
+	if manifest.Config.Workdir != "/app" {
+		t.Errorf("Config.Workdir = %q, want /app", manifest.Config.Workdir)
+	}
+	if len(manifest.Config.Cmd) != 3 || manifest.Config.Cmd[0] != "/bin/sh" {
+		t.Errorf("Config.Cmd = %v, want [/bin/sh -c \"echo hi\"]", manifest.Config.Cmd)
+	}
+}
+
+func TestLoadManifestMissingFile(t *testing.T) {
+	if _, err := LoadManifest(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Fatal("expected an error for a missing manifest file")
+	}
+}
+
+func TestLoadManifestInvalidJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "manifest.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0o644); err != nil {
+		t.Fatalf("writing manifest: %v", err)
+	}
+	if _, err := LoadManifest(path); err == nil {
+		t.Fatal("expected an error for invalid JSON")
+	}
+}
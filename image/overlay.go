@@ -0,0 +1,58 @@
+package image
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+// MountOverlay assembles manifest's layers into a single rootfs for
+// container id: the layers become overlayfs's read-only lowerdir (lowest
+// layer last, since overlayfs takes its lowerdir highest-first), with a
+// fresh upper/work pair giving the container a writable top layer. The
+// merged result is mounted at /var/run/ns-process/<id>/rootfs and that path
+// is returned for use as the container's Spec.Rootfs.
+func MountOverlay(id string, manifest *Manifest) (string, error) {
+	lowers, err := layerDirs(manifest)
+	if err != nil {
+		return "", err
+	}
+	// overlayfs takes lowerdir as a ':'-separated list ordered from
+	// highest to lowest precedence; our manifest lists layers lowest first.
+	reversed := make([]string, len(lowers))
+	for i, dir := range lowers {
+		reversed[len(lowers)-1-i] = dir
+	}
+
+	base := filepath.Join(runRoot, id)
+	upper := filepath.Join(base, "upper")
+	work := filepath.Join(base, "work")
+	merged := filepath.Join(base, "rootfs")
+
+	for _, dir := range []string{upper, work, merged} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return "", fmt.Errorf("creating %s: %w", dir, err)
+		}
+	}
+
+	opts := fmt.Sprintf("lowerdir=%s,upperdir=%s,workdir=%s", strings.Join(reversed, ":"), upper, work)
+	if err := syscall.Mount("overlay", merged, "overlay", 0, opts); err != nil {
+		return "", fmt.Errorf("mounting overlay at %s: %w", merged, err)
+	}
+
+	return merged, nil
+}
+
+// runRoot mirrors container.stateRoot; duplicated here rather than
+// importing the container package, which instead depends on image.
+const runRoot = "/var/run/ns-process"
+
+// UnmountOverlay tears down the overlay mounted at merged by MountOverlay.
+func UnmountOverlay(merged string) error {
+	if err := syscall.Unmount(merged, syscall.MNT_DETACH); err != nil && err != syscall.EINVAL {
+		return fmt.Errorf("unmounting overlay at %s: %w", merged, err)
+	}
+	return nil
+}
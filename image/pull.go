@@ -0,0 +1,138 @@
+package image
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Pull imports a layer tarball into the store, naming its directory after
+// the sha256 digest of the tarball's contents, and returns that digest. If
+// the layer has already been imported, the existing directory is reused
+// and the tarball is only hashed, not unpacked again.
+func Pull(tarballPath string) (string, error) {
+	digest, err := digestOf(tarballPath)
+	if err != nil {
+		return "", fmt.Errorf("hashing %s: %w", tarballPath, err)
+	}
+
+	if HasLayer(digest) {
+		return digest, nil
+	}
+
+	dest := layerDir(digest)
+	tmp := dest + ".tmp"
+	if err := os.RemoveAll(tmp); err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(tmp, 0o755); err != nil {
+		return "", fmt.Errorf("creating layer dir %s: %w", tmp, err)
+	}
+
+	if err := unpack(tarballPath, tmp); err != nil {
+		os.RemoveAll(tmp)
+		return "", fmt.Errorf("unpacking %s: %w", tarballPath, err)
+	}
+
+	if err := os.Rename(tmp, dest); err != nil {
+		os.RemoveAll(tmp)
+		return "", fmt.Errorf("installing layer %s: %w", digest, err)
+	}
+
+	return digest, nil
+}
+
+func digestOf(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// unpack extracts a (possibly gzipped) tar archive into dest.
+func unpack(tarballPath, dest string) error {
+	f, err := os.Open(tarballPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(tarballPath, ".gz") || strings.HasSuffix(tarballPath, ".tgz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		target, err := sanitizeExtractPath(dest, header.Name)
+		if err != nil {
+			return err
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, os.FileMode(header.Mode)); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+				return err
+			}
+			out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.FileMode(header.Mode))
+			if err != nil {
+				return err
+			}
+			if _, err := io.Copy(out, tr); err != nil {
+				out.Close()
+				return err
+			}
+			out.Close()
+		case tar.TypeSymlink:
+			if filepath.IsAbs(header.Linkname) {
+				return fmt.Errorf("tar entry %q: absolute symlink target %q", header.Name, header.Linkname)
+			}
+			if _, err := sanitizeExtractPath(dest, filepath.Join(filepath.Dir(header.Name), header.Linkname)); err != nil {
+				return fmt.Errorf("tar entry %q: symlink target escapes layer dir: %w", header.Name, err)
+			}
+			if err := os.Symlink(header.Linkname, target); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// sanitizeExtractPath joins name onto dest and rejects the result if it
+// escapes dest, guarding tar extraction against path traversal (Zip-Slip).
+func sanitizeExtractPath(dest, name string) (string, error) {
+	target := filepath.Join(dest, name)
+	if target != dest && !strings.HasPrefix(target, dest+string(os.PathSeparator)) {
+		return "", fmt.Errorf("tar entry %q escapes layer dir", name)
+	}
+	return target, nil
+}
@@ -0,0 +1,35 @@
+package image
+
+import "testing"
+
+func TestSanitizeExtractPathRejectsTraversal(t *testing.T) {
+	cases := []string{
+		"../outside",
+		"../../etc/cron.d/evil",
+		"a/../../b",
+	}
+	for _, name := range cases {
+		if _, err := sanitizeExtractPath("/layer", name); err == nil {
+			t.Errorf("sanitizeExtractPath(%q) = nil error, want an error", name)
+		}
+	}
+}
+
+func TestSanitizeExtractPathAllowsWithinDest(t *testing.T) {
+	cases := map[string]string{
+		"file.txt":        "/layer/file.txt",
+		"a/b/c.txt":       "/layer/a/b/c.txt",
+		".":               "/layer",
+		"a/../b/file.txt": "/layer/b/file.txt",
+	}
+	for name, want := range cases {
+		got, err := sanitizeExtractPath("/layer", name)
+		if err != nil {
+			t.Errorf("sanitizeExtractPath(%q): %v", name, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("sanitizeExtractPath(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package image
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// storeRoot is where imported image layers live, one directory per layer
+// keyed by its sha256 digest: /var/lib/ns-process/layers/<sha256>/.
+const storeRoot = "/var/lib/ns-process/layers"
+
+// layerDir returns the path a layer's contents are unpacked into.
+func layerDir(digest string) string {
+	return filepath.Join(storeRoot, digest)
+}
+
+// HasLayer reports whether digest has already been imported into the
+// store, so Pull can skip re-unpacking layers shared between images.
+func HasLayer(digest string) bool {
+	info, err := os.Stat(layerDir(digest))
+	return err == nil && info.IsDir()
+}
+
+// layerDirs resolves a manifest's layer digests to their store paths,
+// lowest layer first, failing if any haven't been pulled yet.
+func layerDirs(manifest *Manifest) ([]string, error) {
+	dirs := make([]string, len(manifest.Layers))
+	for i, digest := range manifest.Layers {
+		if !HasLayer(digest) {
+			return nil, fmt.Errorf("layer %s not found in store; run ns-process pull first", digest)
+		}
+		dirs[i] = layerDir(digest)
+	}
+	return dirs, nil
+}
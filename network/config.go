@@ -0,0 +1,18 @@
+// Package network attaches a container's network namespace to a bridge,
+// in-process via netlink and setns(2) rather than shelling out to a suid
+// helper like netsetgo. Host-wide NAT rules still go through the nft CLI,
+// since there is no in-tree nftables client to drive them with netlink.
+package network
+
+// Config is the network portion of a container Spec.
+type Config struct {
+	// Bridge is the name of the host bridge to attach the container to. It
+	// is created on first use if it doesn't already exist.
+	Bridge string `json:"bridge"`
+	// CIDR is the bridge's address, e.g. "10.11.0.1/24". The container's
+	// address is allocated from the same range by the IPAM allocator.
+	CIDR string `json:"cidr"`
+	// NoMasquerade skips adding the MASQUERADE rule for traffic leaving
+	// the bridge, for setups that handle NAT (or don't need it) themselves.
+	NoMasquerade bool `json:"noMasquerade,omitempty"`
+}
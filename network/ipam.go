@@ -0,0 +1,93 @@
+package network
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+)
+
+// ipamPath is where address allocations are persisted, keyed by bridge, so
+// that restarting ns-process doesn't hand out an address already in use by
+// a running container. Overridden in tests.
+var ipamPath = "/var/run/ns-process/ipam.json"
+
+// ipamState is the on-disk allocator state: bridge name -> last octet
+// handed out.
+type ipamState map[string]int
+
+func loadIPAM() (ipamState, error) {
+	data, err := os.ReadFile(ipamPath)
+	if os.IsNotExist(err) {
+		return ipamState{}, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", ipamPath, err)
+	}
+
+	var state ipamState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", ipamPath, err)
+	}
+	return state, nil
+}
+
+func (state ipamState) save() error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshalling ipam state: %w", err)
+	}
+	return os.WriteFile(ipamPath, data, 0o644)
+}
+
+// allocate returns the next unused address in cidr's range for bridge,
+// skipping the network, broadcast and gateway (.1) addresses.
+func allocate(bridge string, cidr *net.IPNet) (*net.IPNet, error) {
+	state, err := loadIPAM()
+	if err != nil {
+		return nil, err
+	}
+
+	next := state[bridge]
+	if next < 1 {
+		next = 1 // .0 is the network address, .1 is the bridge/gateway
+	}
+	next++
+
+	// next is about to be truncated into a single address byte below, so
+	// it must be bounds-checked before that happens - byte(256) wraps to
+	// 0 and would otherwise hand out the network address (then the
+	// gateway, then the first container's address all over again).
+	if next > 255 {
+		return nil, fmt.Errorf("address pool for bridge %s exhausted", bridge)
+	}
+
+	ip := make(net.IP, len(cidr.IP))
+	copy(ip, cidr.IP)
+	ip[len(ip)-1] = byte(next)
+
+	if !cidr.Contains(ip) || ip.Equal(broadcastAddr(cidr)) {
+		return nil, fmt.Errorf("address pool for bridge %s exhausted", bridge)
+	}
+
+	state[bridge] = next
+	if err := state.save(); err != nil {
+		return nil, err
+	}
+
+	return &net.IPNet{IP: ip, Mask: cidr.Mask}, nil
+}
+
+// broadcastAddr returns cidr's broadcast address - every host bit set to 1 -
+// which net.IPNet.Contains doesn't exclude on its own.
+func broadcastAddr(cidr *net.IPNet) net.IP {
+	base := cidr.IP
+	if len(cidr.Mask) == net.IPv4len {
+		// ensureBridge hands over the 16-byte form of an IPv4 address.
+		base = base.To4()
+	}
+	ip := make(net.IP, len(base))
+	for i := range ip {
+		ip[i] = base[i] | ^cidr.Mask[i]
+	}
+	return ip
+}
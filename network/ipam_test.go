@@ -0,0 +1,93 @@
+package network
+
+import (
+	"net"
+	"path/filepath"
+	"testing"
+)
+
+func withIPAMPath(t *testing.T) {
+	t.Helper()
+	old := ipamPath
+	ipamPath = filepath.Join(t.TempDir(), "ipam.json")
+	t.Cleanup(func() { ipamPath = old })
+}
+
+func TestAllocateFirstAddressIsDotTwo(t *testing.T) {
+	withIPAMPath(t)
+
+	_, cidr, err := net.ParseCIDR("10.11.0.1/24")
+	if err != nil {
+		t.Fatalf("ParseCIDR: %v", err)
+	}
+
+	addr, err := allocate("br0", cidr)
+	if err != nil {
+		t.Fatalf("allocate: %v", err)
+	}
+	if got, want := addr.IP.String(), "10.11.0.2"; got != want {
+		t.Errorf("first allocated address = %s, want %s", got, want)
+	}
+}
+
+func TestAllocateIncrementsAcrossCalls(t *testing.T) {
+	withIPAMPath(t)
+
+	_, cidr, err := net.ParseCIDR("10.11.0.1/24")
+	if err != nil {
+		t.Fatalf("ParseCIDR: %v", err)
+	}
+
+	want := []string{"10.11.0.2", "10.11.0.3", "10.11.0.4"}
+	for _, w := range want {
+		addr, err := allocate("br0", cidr)
+		if err != nil {
+			t.Fatalf("allocate: %v", err)
+		}
+		if got := addr.IP.String(); got != w {
+			t.Errorf("allocate() = %s, want %s", got, w)
+		}
+	}
+}
+
+func TestAllocateExhaustedPool(t *testing.T) {
+	withIPAMPath(t)
+
+	_, cidr, err := net.ParseCIDR("10.11.0.1/30")
+	if err != nil {
+		t.Fatalf("ParseCIDR: %v", err)
+	}
+
+	// /30 covers .0-.3: .0 is the network address, .1 the gateway, .3 the
+	// broadcast address - only .2 is actually allocatable.
+	if _, err := allocate("br0", cidr); err != nil {
+		t.Fatalf("allocate: %v", err)
+	}
+	if _, err := allocate("br0", cidr); err == nil {
+		t.Error("expected allocate to fail once the pool is exhausted")
+	}
+}
+
+func TestAllocateExcludesBroadcast(t *testing.T) {
+	withIPAMPath(t)
+
+	_, cidr, err := net.ParseCIDR("10.11.0.1/24")
+	if err != nil {
+		t.Fatalf("ParseCIDR: %v", err)
+	}
+
+	// .2 through .254 are allocatable (253 addresses); .255 is the
+	// broadcast address and must never be handed out.
+	for i := 0; i < 253; i++ {
+		addr, err := allocate("br0", cidr)
+		if err != nil {
+			t.Fatalf("allocate %d: %v", i, err)
+		}
+		if addr.IP.String() == "10.11.0.255" {
+			t.Fatalf("allocate handed out the broadcast address")
+		}
+	}
+	if _, err := allocate("br0", cidr); err == nil {
+		t.Error("expected allocate to fail once .2-.254 are exhausted, not wrap around")
+	}
+}
@@ -0,0 +1,228 @@
+package network
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"runtime"
+
+	"github.com/vishvananda/netlink"
+	"github.com/vishvananda/netns"
+)
+
+// Attach wires up networking for the container's init process, identified
+// by pid, per cfg:
+//
+//  1. create or reuse the bridge cfg.Bridge with address cfg.CIDR
+//  2. create a veth pair
+//  3. move one end into the container's network namespace
+//  4. allocate an address for the container's end from the IPAM pool and
+//     bring both ends up, with a default route via the bridge
+//  5. enable MASQUERADE for traffic leaving the bridge, so the container
+//     can reach the outside world
+//
+// It must be called from the host network namespace, after the container's
+// process has been started (so /proc/<pid>/ns/net exists) but before it is
+// allowed to proceed past its init barrier.
+func Attach(pid int, cfg *Config) error {
+	bridge, bridgeNet, err := ensureBridge(cfg.Bridge, cfg.CIDR)
+	if err != nil {
+		return fmt.Errorf("ensuring bridge %s: %w", cfg.Bridge, err)
+	}
+
+	hostVeth, nsVeth, err := createVethPair(pid)
+	if err != nil {
+		return fmt.Errorf("creating veth pair: %w", err)
+	}
+
+	if err := netlink.LinkSetMaster(hostVeth, bridge); err != nil {
+		return fmt.Errorf("attaching %s to bridge %s: %w", hostVeth.Attrs().Name, cfg.Bridge, err)
+	}
+	if err := netlink.LinkSetUp(hostVeth); err != nil {
+		return fmt.Errorf("bringing up %s: %w", hostVeth.Attrs().Name, err)
+	}
+
+	addr, err := allocate(cfg.Bridge, bridgeNet)
+	if err != nil {
+		return err
+	}
+
+	if err := configureContainerEnd(pid, nsVeth.Attrs().Name, addr, bridgeNet.IP); err != nil {
+		return err
+	}
+
+	if !cfg.NoMasquerade {
+		if err := enableMasquerade(cfg.Bridge, bridgeNet); err != nil {
+			return fmt.Errorf("enabling masquerade for %s: %w", cfg.Bridge, err)
+		}
+	}
+
+	return nil
+}
+
+// ensureBridge returns the bridge named name, creating it with address cidr
+// if it doesn't already exist.
+func ensureBridge(name, cidr string) (*netlink.Bridge, *net.IPNet, error) {
+	addr, bridgeNet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parsing cidr %s: %w", cidr, err)
+	}
+	bridgeNet.IP = addr
+
+	link, err := netlink.LinkByName(name)
+	if err == nil {
+		if bridge, ok := link.(*netlink.Bridge); ok {
+			return bridge, bridgeNet, nil
+		}
+		return nil, nil, fmt.Errorf("%s exists and is not a bridge", name)
+	}
+
+	bridge := &netlink.Bridge{LinkAttrs: netlink.LinkAttrs{Name: name}}
+	if err := netlink.LinkAdd(bridge); err != nil {
+		return nil, nil, err
+	}
+	if err := netlink.AddrAdd(bridge, &netlink.Addr{IPNet: bridgeNet}); err != nil {
+		return nil, nil, err
+	}
+	if err := netlink.LinkSetUp(bridge); err != nil {
+		return nil, nil, err
+	}
+
+	return bridge, bridgeNet, nil
+}
+
+// createVethPair creates a veth pair and moves one end into pid's network
+// namespace, returning the end that stays on the host and the end that
+// moved.
+func createVethPair(pid int) (netlink.Link, netlink.Link, error) {
+	hostName := fmt.Sprintf("veth%d", pid)
+	peerName := fmt.Sprintf("veth%dns", pid)
+
+	veth := &netlink.Veth{
+		LinkAttrs: netlink.LinkAttrs{Name: hostName},
+		PeerName:  peerName,
+	}
+	if err := netlink.LinkAdd(veth); err != nil {
+		return nil, nil, err
+	}
+
+	hostVeth, err := netlink.LinkByName(hostName)
+	if err != nil {
+		return nil, nil, err
+	}
+	nsVeth, err := netlink.LinkByName(peerName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	nsFile, err := os.Open(fmt.Sprintf("/proc/%d/ns/net", pid))
+	if err != nil {
+		return nil, nil, fmt.Errorf("opening netns for pid %d: %w", pid, err)
+	}
+	defer nsFile.Close()
+
+	if err := netlink.LinkSetNsFd(nsVeth, int(nsFile.Fd())); err != nil {
+		return nil, nil, fmt.Errorf("moving %s into netns of pid %d: %w", peerName, pid, err)
+	}
+
+	return hostVeth, nsVeth, nil
+}
+
+// configureContainerEnd assigns addr to the veth end that was moved into
+// pid's network namespace (named vethName there), brings up loopback and
+// the veth as eth0, and adds a default route via gateway.
+//
+// This all has to happen with the calling goroutine's OS thread switched
+// into pid's netns via setns(2), which is why the thread is locked for the
+// duration: netlink's default handle operates against "the calling
+// thread's" netns, and an unlocked goroutine could be rescheduled onto a
+// different thread - one still in the host's netns - mid-call.
+func configureContainerEnd(pid int, vethName string, addr *net.IPNet, gateway net.IP) error {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	hostNs, err := netns.Get()
+	if err != nil {
+		return fmt.Errorf("getting host netns: %w", err)
+	}
+	defer hostNs.Close()
+
+	containerNs, err := netns.GetFromPid(pid)
+	if err != nil {
+		return fmt.Errorf("getting netns of pid %d: %w", pid, err)
+	}
+	defer containerNs.Close()
+
+	if err := netns.Set(containerNs); err != nil {
+		return fmt.Errorf("entering netns of pid %d: %w", pid, err)
+	}
+	defer netns.Set(hostNs)
+
+	lo, err := netlink.LinkByName("lo")
+	if err != nil {
+		return fmt.Errorf("finding lo in netns of pid %d: %w", pid, err)
+	}
+	if err := netlink.LinkSetUp(lo); err != nil {
+		return fmt.Errorf("bringing up lo in netns of pid %d: %w", pid, err)
+	}
+
+	veth, err := netlink.LinkByName(vethName)
+	if err != nil {
+		return fmt.Errorf("finding %s in netns of pid %d: %w", vethName, pid, err)
+	}
+	if err := netlink.LinkSetName(veth, "eth0"); err != nil {
+		return fmt.Errorf("renaming %s to eth0 in netns of pid %d: %w", vethName, pid, err)
+	}
+
+	eth0, err := netlink.LinkByName("eth0")
+	if err != nil {
+		return fmt.Errorf("finding eth0 in netns of pid %d: %w", pid, err)
+	}
+	if err := netlink.AddrAdd(eth0, &netlink.Addr{IPNet: addr}); err != nil {
+		return fmt.Errorf("adding address %s to eth0 in netns of pid %d: %w", addr, pid, err)
+	}
+	if err := netlink.LinkSetUp(eth0); err != nil {
+		return fmt.Errorf("bringing up eth0 in netns of pid %d: %w", pid, err)
+	}
+
+	route := &netlink.Route{LinkIndex: eth0.Attrs().Index, Gw: gateway}
+	if err := netlink.RouteAdd(route); err != nil {
+		return fmt.Errorf("adding default route via %s in netns of pid %d: %w", gateway, pid, err)
+	}
+
+	return nil
+}
+
+// enableMasquerade ensures traffic leaving bridgeNet via any other
+// interface is source-NATed, so containers can reach outside networks
+// through the host.
+func enableMasquerade(bridge string, bridgeNet *net.IPNet) error {
+	if err := ensureNftChain(); err != nil {
+		return err
+	}
+
+	rule := fmt.Sprintf("ip saddr %s oif != \"%s\" masquerade", bridgeNet.String(), bridge)
+	cmd := exec.Command("nft", "add", "rule", "inet", "ns-process", "postrouting", rule)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("nft add rule: %w: %s", err, out)
+	}
+	return nil
+}
+
+// ensureNftChain creates the ns-process table and postrouting chain if they
+// don't already exist; `nft add` is idempotent so this is safe to call on
+// every Attach.
+func ensureNftChain() error {
+	steps := [][]string{
+		{"add", "table", "inet", "ns-process"},
+		{"add", "chain", "inet", "ns-process", "postrouting", "{", "type", "nat", "hook", "postrouting", "priority", "100", ";", "}"},
+	}
+	for _, step := range steps {
+		cmd := exec.Command("nft", step...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("nft %v: %w: %s", step, err, out)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+// Package nsenter lets a process join the namespaces of an already-running
+// container and exec a command inside it, mirroring the setns(2) use case
+// described in namespaces(7).
+//
+// The tricky part is that setns(2) only changes the namespace membership of
+// the calling thread, while the Go runtime is free to move a goroutine
+// between OS threads at any point after it has spun up - so joining
+// namespaces from ordinary Go code can't be relied on to affect the whole
+// process. Joining is instead done from a cgo constructor, which glibc runs
+// before the Go runtime initialises and before any extra OS threads exist,
+// exactly as runc's nsenter package does.
+package nsenter
+
+/*
+#define _GNU_SOURCE
+#include <sched.h>
+#include <stdio.h>
+#include <stdlib.h>
+#include <string.h>
+#include <unistd.h>
+#include <errno.h>
+#include <sys/wait.h>
+#include <sys/stat.h>
+#include <fcntl.h>
+
+// joinEnv names the environment variable that signals this is an nsenter
+// invocation and carries the pid to join, in "_NS_PROCESS_NSENTER_PID=<pid>"
+// form. Its presence is checked before the Go runtime has done anything
+// that would make setns unsafe.
+#define JOIN_ENV "_NS_PROCESS_NSENTER_PID"
+
+// The namespaces are joined in this order: user first (so we still have
+// the privilege to join the rest, if the target was started unprivileged),
+// then everything else, pid last since setns(CLONE_NEWPID) only affects
+// children created after the call, never the calling process itself.
+static const char *ns_order[] = {"user", "mnt", "uts", "ipc", "net", "cgroup", "pid"};
+static const int ns_count = 7;
+
+static void die(const char *msg) {
+	fprintf(stderr, "nsenter: %s: %s\n", msg, strerror(errno));
+	_exit(1);
+}
+
+// nsenter_init runs as a constructor - before main(), before the Go
+// runtime, before any thread but this one exists. If JOIN_ENV isn't set,
+// it's a no-op and the binary continues to its normal Go main().
+//
+// If it is set, this process joins the target pid's namespaces, forks (so
+// that the pid namespace actually takes effect), and the child execs the
+// command given after "--" in argv, never returning to Go at all. The
+// parent waits for the child and exits with its status, acting as a thin
+// relay back to whoever invoked us.
+__attribute__((constructor)) static void nsenter_init(int argc, char **argv, char **envp) {
+	char *pid_str = getenv(JOIN_ENV);
+	if (pid_str == NULL) {
+		return;
+	}
+
+	int cmd_start = -1;
+	for (int i = 1; i < argc; i++) {
+		if (strcmp(argv[i], "--") == 0) {
+			cmd_start = i + 1;
+			break;
+		}
+	}
+	if (cmd_start < 0 || cmd_start >= argc) {
+		die("missing -- <cmd> [args...] in argv");
+	}
+
+	for (int i = 0; i < ns_count; i++) {
+		char path[64];
+		snprintf(path, sizeof(path), "/proc/%s/ns/%s", pid_str, ns_order[i]);
+
+		char self_path[64];
+		snprintf(self_path, sizeof(self_path), "/proc/self/ns/%s", ns_order[i]);
+		struct stat target_st, self_st;
+		if (stat(path, &target_st) == 0 && stat(self_path, &self_st) == 0 &&
+		    target_st.st_dev == self_st.st_dev && target_st.st_ino == self_st.st_ino) {
+			// This namespace wasn't unshared for the target container;
+			// nothing to join.
+			continue;
+		}
+
+		int fd = open(path, O_RDONLY);
+		if (fd == -1) {
+			if (errno == ENOENT) {
+				// This kernel has no such namespace type; nothing to join.
+				continue;
+			}
+			die("open ns file");
+		}
+		if (setns(fd, 0) == -1) {
+			die("setns");
+		}
+		close(fd);
+	}
+
+	pid_t child = fork();
+	if (child == -1) {
+		die("fork");
+	}
+
+	if (child == 0) {
+		execve(argv[cmd_start], &argv[cmd_start], envp);
+		die("execve");
+	}
+
+	int status = 0;
+	if (waitpid(child, &status, 0) == -1) {
+		die("waitpid");
+	}
+	_exit(WIFEXITED(status) ? WEXITSTATUS(status) : 1);
+}
+*/
+import "C"
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+)
+
+// joinEnv is the Go-side name of the environment variable the cgo
+// constructor looks for; see JOIN_ENV above.
+const joinEnv = "_NS_PROCESS_NSENTER_PID"
+
+// Join re-executes the running binary with joinEnv set to pid, so that its
+// cgo constructor joins pid's namespaces and execs cmd with args before the
+// Go runtime in the new process image ever starts. It does not return
+// until that process exits.
+func Join(pid int, env []string, cmd string, args []string) error {
+	self, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("resolving own executable path: %w", err)
+	}
+
+	argv := append([]string{"--", cmd}, args...)
+	reexec := exec.Command(self, argv...)
+	reexec.Env = append(append([]string{}, env...), joinEnv+"="+strconv.Itoa(pid))
+	reexec.Stdin = os.Stdin
+	reexec.Stdout = os.Stdout
+	reexec.Stderr = os.Stderr
+
+	return reexec.Run()
+}
@@ -0,0 +1,167 @@
+// Package security applies the hardening nsInitialisation puts in place
+// after pivot_root and before exec: capability dropping, no_new_privs, and
+// an optional seccomp filter.
+package security
+
+import (
+	"fmt"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// Capabilities configures capability dropping for a container. Anything
+// not named in Bounding is removed from the bounding set via
+// PR_CAPBSET_DROP, which prevents it from ever being regained (by the
+// container or anything it execs).
+// Effective and Permitted additionally restrict what the init process
+// itself holds going into exec, via capset(2).
+type Capabilities struct {
+	Bounding  []string `json:"bounding,omitempty"`
+	Effective []string `json:"effective,omitempty"`
+	Permitted []string `json:"permitted,omitempty"`
+}
+
+// capabilityNames maps the CAP_* names used in a Spec to their capability
+// number, per capability(7). This is the full range up to CAP_LAST_CAP on
+// a current kernel (cap_last_cap in /proc/sys/kernel); DropCapabilities
+// only drops what's named here, so a gap here is a capability that never
+// gets dropped regardless of what a Spec's Bounding list says. An unknown
+// name is rejected rather than silently ignored.
+var capabilityNames = map[string]uintptr{
+	"CAP_CHOWN":              0,
+	"CAP_DAC_OVERRIDE":       1,
+	"CAP_DAC_READ_SEARCH":    2,
+	"CAP_FOWNER":             3,
+	"CAP_FSETID":             4,
+	"CAP_KILL":               5,
+	"CAP_SETGID":             6,
+	"CAP_SETUID":             7,
+	"CAP_SETPCAP":            8,
+	"CAP_LINUX_IMMUTABLE":    9,
+	"CAP_NET_BIND_SERVICE":   10,
+	"CAP_NET_BROADCAST":      11,
+	"CAP_NET_ADMIN":          12,
+	"CAP_NET_RAW":            13,
+	"CAP_IPC_LOCK":           14,
+	"CAP_IPC_OWNER":          15,
+	"CAP_SYS_MODULE":         16,
+	"CAP_SYS_RAWIO":          17,
+	"CAP_SYS_CHROOT":         18,
+	"CAP_SYS_PTRACE":         19,
+	"CAP_SYS_PACCT":          20,
+	"CAP_SYS_ADMIN":          21,
+	"CAP_SYS_BOOT":           22,
+	"CAP_SYS_NICE":           23,
+	"CAP_SYS_RESOURCE":       24,
+	"CAP_SYS_TIME":           25,
+	"CAP_SYS_TTY_CONFIG":     26,
+	"CAP_MKNOD":              27,
+	"CAP_LEASE":              28,
+	"CAP_AUDIT_WRITE":        29,
+	"CAP_AUDIT_CONTROL":      30,
+	"CAP_SETFCAP":            31,
+	"CAP_MAC_OVERRIDE":       32,
+	"CAP_MAC_ADMIN":          33,
+	"CAP_SYSLOG":             34,
+	"CAP_WAKE_ALARM":         35,
+	"CAP_BLOCK_SUSPEND":      36,
+	"CAP_AUDIT_READ":         37,
+	"CAP_PERFMON":            38,
+	"CAP_BPF":                39,
+	"CAP_CHECKPOINT_RESTORE": 40,
+}
+
+func capabilityNumber(name string) (uintptr, error) {
+	n, ok := capabilityNames[name]
+	if !ok {
+		return 0, fmt.Errorf("unknown capability %s", name)
+	}
+	return n, nil
+}
+
+// DropCapabilities shrinks the calling process's capability sets to match
+// caps: every named capability not in Bounding is dropped from the
+// bounding set via PR_CAPBSET_DROP, and the effective/permitted sets are
+// then replaced outright via capset(2).
+func DropCapabilities(caps *Capabilities) error {
+	if caps == nil {
+		return nil
+	}
+
+	keep := make(map[string]bool, len(caps.Bounding))
+	for _, name := range caps.Bounding {
+		if _, err := capabilityNumber(name); err != nil {
+			return fmt.Errorf("building bounding set: %w", err)
+		}
+		keep[name] = true
+	}
+	for name := range capabilityNames {
+		if keep[name] {
+			continue
+		}
+		num, _ := capabilityNumber(name)
+		if _, _, errno := unix.Syscall(unix.SYS_PRCTL, unix.PR_CAPBSET_DROP, num, 0); errno != 0 {
+			return fmt.Errorf("dropping %s from bounding set: %w", name, errno)
+		}
+	}
+
+	effective, err := capMask(caps.Effective)
+	if err != nil {
+		return fmt.Errorf("building effective capability mask: %w", err)
+	}
+	permitted, err := capMask(caps.Permitted)
+	if err != nil {
+		return fmt.Errorf("building permitted capability mask: %w", err)
+	}
+
+	header := capUserHeader{version: capVersion3, pid: 0}
+	data := [2]capUserData{
+		{effective: uint32(effective), permitted: uint32(permitted)},
+		{effective: uint32(effective >> 32), permitted: uint32(permitted >> 32)},
+	}
+
+	if _, _, errno := unix.Syscall(unix.SYS_CAPSET, uintptr(unsafe.Pointer(&header)), uintptr(unsafe.Pointer(&data[0])), 0); errno != 0 {
+		return fmt.Errorf("capset: %w", errno)
+	}
+
+	return nil
+}
+
+func capMask(names []string) (uint64, error) {
+	var mask uint64
+	for _, name := range names {
+		num, err := capabilityNumber(name)
+		if err != nil {
+			return 0, err
+		}
+		mask |= 1 << num
+	}
+	return mask, nil
+}
+
+// capVersion3 is the _LINUX_CAPABILITY_VERSION_3 constant from
+// linux/capability.h, required for the 64-bit capability sets capset(2)
+// expects as of Linux 2.6.26.
+const capVersion3 = 0x20080522
+
+type capUserHeader struct {
+	version uint32
+	pid     int32
+}
+
+type capUserData struct {
+	effective   uint32
+	permitted   uint32
+	inheritable uint32
+}
+
+// SetNoNewPrivs sets PR_SET_NO_NEW_PRIVS, preventing the process (and
+// anything it execs) from gaining privileges it didn't already have -
+// required before a seccomp filter can be installed without CAP_SYS_ADMIN.
+func SetNoNewPrivs() error {
+	if _, _, errno := unix.Syscall(unix.SYS_PRCTL, unix.PR_SET_NO_NEW_PRIVS, 1, 0); errno != 0 {
+		return fmt.Errorf("setting no_new_privs: %w", errno)
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package security
+
+import "testing"
+
+// TestCapabilityNamesContiguous guards against silently reintroducing a gap
+// in the bounding-set table: DropCapabilities only ever drops numbers it
+// knows a name for, so a missing number here is a capability that can never
+// be dropped regardless of what a Spec's Bounding list says.
+func TestCapabilityNamesContiguous(t *testing.T) {
+	var max uintptr
+	seen := make(map[uintptr]bool, len(capabilityNames))
+	for _, num := range capabilityNames {
+		seen[num] = true
+		if num > max {
+			max = num
+		}
+	}
+
+	for n := uintptr(0); n <= max; n++ {
+		if !seen[n] {
+			t.Errorf("capabilityNames has no entry for capability number %d", n)
+		}
+	}
+}
+
+func TestCapabilityNumberUnknown(t *testing.T) {
+	if _, err := capabilityNumber("CAP_NOT_REAL"); err == nil {
+		t.Fatal("expected an error for an unknown capability name")
+	}
+}
@@ -0,0 +1,87 @@
+package security
+
+import "testing"
+
+func TestCompileUnknownDefaultAction(t *testing.T) {
+	_, err := compile(&Seccomp{DefaultAction: "bogus"})
+	if err == nil {
+		t.Fatal("expected an error for an unknown default action")
+	}
+}
+
+func TestCompileUnknownSyscall(t *testing.T) {
+	profile := &Seccomp{
+		DefaultAction: "allow",
+		Syscalls:      []SyscallRule{{Names: []string{"not_a_real_syscall"}, Action: "errno"}},
+	}
+	if _, err := compile(profile); err == nil {
+		t.Fatal("expected an error for an unknown syscall name")
+	}
+}
+
+func TestCompileUnknownRuleAction(t *testing.T) {
+	profile := &Seccomp{
+		DefaultAction: "allow",
+		Syscalls:      []SyscallRule{{Names: []string{"read"}, Action: "bogus"}},
+	}
+	if _, err := compile(profile); err == nil {
+		t.Fatal("expected an error for an unknown rule action")
+	}
+}
+
+func TestCompileProgramShape(t *testing.T) {
+	profile := &Seccomp{
+		DefaultAction: "errno",
+		Syscalls:      []SyscallRule{{Names: []string{"read", "write"}, Action: "allow"}},
+	}
+
+	program, err := compile(profile)
+	if err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+
+	// 4 fixed instructions (arch load, arch jump, kill, syscall-number load) + 2
+	// instructions per named syscall + 1 trailing default-action return.
+	wantLen := 4 + 2*2 + 1
+	if len(program) != wantLen {
+		t.Fatalf("len(program) = %d, want %d", len(program), wantLen)
+	}
+
+	last := program[len(program)-1]
+	if last.Code != bpfRET|bpfK || last.K != seccompRetErrno {
+		t.Errorf("trailing instruction = %+v, want RET errno", last)
+	}
+	// The low 16 bits carry the errno returned to the denied syscall;
+	// zero there means the syscall would appear to succeed instead of
+	// failing.
+	if last.K&0xffff == 0 {
+		t.Errorf("seccompRetErrno has no errno in its low 16 bits: %#x", last.K)
+	}
+
+	readNr := syscallNumbers["read"]
+	found := false
+	for _, instr := range program {
+		if instr.Code == uint16(bpfJMP|bpfJEQ|bpfK) && instr.K == readNr {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("expected a jump instruction comparing against read's syscall number")
+	}
+}
+
+func TestSeccompReturnValue(t *testing.T) {
+	cases := map[string]uint32{"allow": seccompRetAllow, "errno": seccompRetErrno}
+	for action, want := range cases {
+		got, err := seccompReturnValue(action)
+		if err != nil {
+			t.Fatalf("seccompReturnValue(%q): %v", action, err)
+		}
+		if got != want {
+			t.Errorf("seccompReturnValue(%q) = %#x, want %#x", action, got, want)
+		}
+	}
+	if _, err := seccompReturnValue("bogus"); err == nil {
+		t.Error("expected an error for an unknown action")
+	}
+}
@@ -0,0 +1,90 @@
+package security
+
+// syscallNumbers maps syscall names to their x86_64 syscall numbers, from
+// arch/x86/entry/syscalls/syscall_64.tbl. It covers the syscalls a typical
+// container workload needs; referencing a name not listed here is a
+// profile error rather than something we should guess at.
+var syscallNumbers = map[string]uint32{
+	"read":              0,
+	"write":             1,
+	"open":              2,
+	"close":             3,
+	"stat":              4,
+	"fstat":             5,
+	"lstat":             6,
+	"poll":              7,
+	"lseek":             8,
+	"mmap":              9,
+	"mprotect":          10,
+	"munmap":            11,
+	"brk":               12,
+	"rt_sigaction":      13,
+	"rt_sigprocmask":    14,
+	"ioctl":             16,
+	"access":            21,
+	"pipe":              22,
+	"select":            23,
+	"mremap":            25,
+	"dup":               32,
+	"dup2":              33,
+	"nanosleep":         35,
+	"getpid":            39,
+	"socket":            41,
+	"connect":           42,
+	"accept":            43,
+	"sendto":            44,
+	"recvfrom":          45,
+	"bind":              49,
+	"listen":            50,
+	"clone":             56,
+	"fork":              57,
+	"vfork":             58,
+	"execve":            59,
+	"exit":              60,
+	"wait4":             61,
+	"kill":              62,
+	"uname":             63,
+	"fcntl":             72,
+	"getdents":          78,
+	"getcwd":            79,
+	"chdir":             80,
+	"mkdir":             83,
+	"rmdir":             84,
+	"unlink":            87,
+	"readlink":          89,
+	"chmod":             90,
+	"chown":             92,
+	"umask":             95,
+	"gettimeofday":      96,
+	"getuid":            102,
+	"getgid":            104,
+	"setuid":            105,
+	"setgid":            106,
+	"getppid":           110,
+	"statfs":            137,
+	"sched_yield":       24,
+	"sched_getaffinity": 204,
+	"mount":             165,
+	"umount2":           166,
+	"pivot_root":        155,
+	"prctl":             157,
+	"arch_prctl":        158,
+	"gettid":            186,
+	"futex":             202,
+	"set_tid_address":   218,
+	"exit_group":        231,
+	"openat":            257,
+	"set_robust_list":   273,
+	"pselect6":          270,
+	"epoll_create":      213,
+	"epoll_wait":        232,
+	"epoll_ctl":         233,
+	"clock_gettime":     228,
+	"capset":            126,
+	"capget":            125,
+	"setsockopt":        54,
+	"getsockopt":        55,
+	"getsockname":       51,
+	"getpeername":       52,
+	"sysinfo":           99,
+}